@@ -0,0 +1,125 @@
+// Copyright (c) OpenFaaS Author(s). All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+// Package cache provides shared, out-of-process implementations of handlers.Cache
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/openfaas/faas/gateway/handlers"
+)
+
+// luaSetIfNewer implements SetIfNewer atomically via a Lua script
+var luaSetIfNewer = redis.NewScript(`
+local existing = redis.call("GET", KEYS[1])
+if existing then
+	local decoded = cjson.decode(existing)
+	if decoded.availableReplicas > tonumber(ARGV[2]) then
+		return 0
+	end
+end
+redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[3])
+return 1
+`)
+
+// entry is the JSON wire format stored against a function's cache key
+type entry struct {
+	AvailableReplicas uint64 `json:"availableReplicas"`
+	Response          handlers.ServiceQueryResponse
+}
+
+// RedisCache is a handlers.Cache backed by Redis, shared across gateway replicas
+type RedisCache struct {
+	Client *redis.Client
+	Expiry time.Duration
+
+	// KeyPrefix namespaces cache keys, useful when multiple gateways
+	// share one Redis instance
+	KeyPrefix string
+}
+
+// NewRedisCache creates a RedisCache using client, expiring entries after
+// expiry
+func NewRedisCache(client *redis.Client, expiry time.Duration) *RedisCache {
+	return &RedisCache{
+		Client:    client,
+		Expiry:    expiry,
+		KeyPrefix: "faas-scaling:",
+	}
+}
+
+func (c *RedisCache) key(functionName string) string {
+	return c.KeyPrefix + functionName
+}
+
+func (c *RedisCache) lockKey(functionName string) string {
+	return c.KeyPrefix + "lock:" + functionName
+}
+
+// Get returns the cached response for functionName
+func (c *RedisCache) Get(functionName string) (handlers.ServiceQueryResponse, bool) {
+	raw, err := c.Client.Get(context.Background(), c.key(functionName)).Bytes()
+	if err != nil {
+		return handlers.ServiceQueryResponse{}, false
+	}
+
+	var e entry
+	if err := json.Unmarshal(raw, &e); err != nil {
+		return handlers.ServiceQueryResponse{}, false
+	}
+
+	return e.Response, true
+}
+
+// Set unconditionally stores response for functionName using "SET PX"
+func (c *RedisCache) Set(functionName string, response handlers.ServiceQueryResponse) {
+	raw, err := json.Marshal(entry{AvailableReplicas: response.AvailableReplicas, Response: response})
+	if err != nil {
+		return
+	}
+
+	c.Client.Set(context.Background(), c.key(functionName), raw, c.Expiry)
+}
+
+// Delete removes any cached entry for functionName
+func (c *RedisCache) Delete(functionName string) {
+	c.Client.Del(context.Background(), c.key(functionName))
+}
+
+// SetIfNewer stores response for functionName unless an existing entry
+// already reports a higher AvailableReplicas count
+func (c *RedisCache) SetIfNewer(functionName string, response handlers.ServiceQueryResponse) bool {
+	raw, err := json.Marshal(entry{AvailableReplicas: response.AvailableReplicas, Response: response})
+	if err != nil {
+		return false
+	}
+
+	ctx := context.Background()
+	ttlMillis := c.Expiry.Milliseconds()
+
+	res, err := luaSetIfNewer.Run(ctx, c.Client, []string{c.key(functionName)}, raw, response.AvailableReplicas, ttlMillis).Int()
+	if err != nil {
+		return false
+	}
+
+	return res == 1
+}
+
+// Claim acquires a "SET NX PX" lock on functionName, so that only one
+// gateway replica racing to scale the same cold function wins and calls
+// SetReplicas; the rest should poll the cache instead.
+func (c *RedisCache) Claim(functionName string, ttl time.Duration) bool {
+	ok, err := c.Client.SetNX(context.Background(), c.lockKey(functionName), "1", ttl).Result()
+	return err == nil && ok
+}
+
+// Release gives up a claim obtained via Claim
+func (c *RedisCache) Release(functionName string) {
+	c.Client.Del(context.Background(), c.lockKey(functionName))
+}
+
+var _ handlers.Cache = (*RedisCache)(nil)
@@ -0,0 +1,93 @@
+// Copyright (c) OpenFaaS Author(s). All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/openfaas/faas/gateway/handlers"
+)
+
+func newTestRedisCache(t *testing.T) *RedisCache {
+	t.Helper()
+
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("unable to start miniredis: %s", err)
+	}
+	t.Cleanup(mr.Close)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return NewRedisCache(client, time.Minute)
+}
+
+func Test_RedisCache_Set_OverwritesExistingEntry(t *testing.T) {
+	c := newTestRedisCache(t)
+
+	c.Set("fn1", handlers.ServiceQueryResponse{AvailableReplicas: 3})
+	c.Set("fn1", handlers.ServiceQueryResponse{AvailableReplicas: 0})
+
+	response, hit := c.Get("fn1")
+	if !hit {
+		t.Fatal("expected a cache hit")
+	}
+	if response.AvailableReplicas != 0 {
+		t.Fatalf("expected Set to overwrite the existing entry, got AvailableReplicas=%d", response.AvailableReplicas)
+	}
+}
+
+func Test_RedisCache_SetIfNewer_WinsOnHigherCount(t *testing.T) {
+	c := newTestRedisCache(t)
+
+	c.Set("fn1", handlers.ServiceQueryResponse{AvailableReplicas: 1})
+
+	ok := c.SetIfNewer("fn1", handlers.ServiceQueryResponse{AvailableReplicas: 2})
+	if !ok {
+		t.Fatal("expected SetIfNewer to report a store")
+	}
+
+	response, hit := c.Get("fn1")
+	if !hit || response.AvailableReplicas != 2 {
+		t.Fatalf("expected AvailableReplicas 2, got %+v (hit=%v)", response, hit)
+	}
+}
+
+func Test_RedisCache_SetIfNewer_StaleWriteLoses(t *testing.T) {
+	c := newTestRedisCache(t)
+
+	c.Set("fn1", handlers.ServiceQueryResponse{AvailableReplicas: 3})
+
+	ok := c.SetIfNewer("fn1", handlers.ServiceQueryResponse{AvailableReplicas: 1})
+	if ok {
+		t.Fatal("expected SetIfNewer to reject a lower AvailableReplicas count")
+	}
+
+	response, hit := c.Get("fn1")
+	if !hit || response.AvailableReplicas != 3 {
+		t.Fatalf("expected stale write to leave AvailableReplicas at 3, got %+v (hit=%v)", response, hit)
+	}
+}
+
+func Test_RedisCache_Claim_SerialisesOwnership(t *testing.T) {
+	c := newTestRedisCache(t)
+
+	if !c.Claim("fn1", time.Minute) {
+		t.Fatal("expected first Claim to succeed")
+	}
+
+	if c.Claim("fn1", time.Minute) {
+		t.Fatal("expected second concurrent Claim to fail")
+	}
+
+	c.Release("fn1")
+
+	if !c.Claim("fn1", time.Minute) {
+		t.Fatal("expected Claim to succeed again after Release")
+	}
+}
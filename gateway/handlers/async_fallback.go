@@ -0,0 +1,74 @@
+// Copyright (c) OpenFaaS Author(s). All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/openfaas/faas/gateway/queue"
+)
+
+// callbackURLHeader is set by clients that want a slow cold-start to be
+// queued for async delivery, rather than the caller blocking until it
+// times out
+const callbackURLHeader = "X-Callback-Url"
+
+// asyncHeader opts a request into async fallback without supplying its
+// own callback, e.g. for fire-and-forget invocations
+const asyncHeader = "X-Async"
+
+// callIDHeader carries the id of a request queued by the async fallback
+// back to the caller, mirroring the gateway's existing async invocation path
+const callIDHeader = "X-Call-Id"
+
+// wantsAsyncFallback reports whether r has opted into the async fallback
+// path, either by supplying a callback URL or by setting X-Async
+func wantsAsyncFallback(r *http.Request) bool {
+	return r.Header.Get(callbackURLHeader) != "" || r.Header.Get(asyncHeader) != ""
+}
+
+// enqueueAsyncFallback queues r for later, asynchronous delivery to
+// functionName and returns the call id the caller can use to correlate
+// the eventual callback.
+func enqueueAsyncFallback(queuer queue.RequestQueuer, functionName string, r *http.Request) (string, error) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return "", fmt.Errorf("unable to read request body: %s", err)
+	}
+
+	callID, err := newCallID()
+	if err != nil {
+		return "", fmt.Errorf("unable to create call id: %s", err)
+	}
+
+	req := &queue.Request{
+		Function:    functionName,
+		Body:        body,
+		Method:      r.Method,
+		QueryString: r.URL.RawQuery,
+		Header:      r.Header,
+		CallbackURL: r.Header.Get(callbackURLHeader),
+		CallID:      callID,
+	}
+
+	if err := queuer.Queue(req); err != nil {
+		return "", err
+	}
+
+	return callID, nil
+}
+
+// newCallID generates a random identifier for a queued async request
+func newCallID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,76 @@
+// Copyright (c) OpenFaaS Author(s). All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openfaas/faas/gateway/queue"
+)
+
+func Test_wantsAsyncFallback(t *testing.T) {
+	cases := []struct {
+		name   string
+		header string
+		value  string
+		want   bool
+	}{
+		{"no header", "", "", false},
+		{"callback url", callbackURLHeader, "http://example.com/callback", true},
+		{"async header", asyncHeader, "true", true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/function/fn1", nil)
+			if c.header != "" {
+				r.Header.Set(c.header, c.value)
+			}
+
+			if got := wantsAsyncFallback(r); got != c.want {
+				t.Errorf("wantsAsyncFallback() = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func Test_enqueueAsyncFallback_QueuesRequest(t *testing.T) {
+	queuer := &queue.InMemoryQueuer{}
+
+	body := "some payload"
+	r := httptest.NewRequest(http.MethodPost, "/function/fn1?foo=bar", strings.NewReader(body))
+	r.Header.Set(callbackURLHeader, "http://example.com/callback")
+
+	callID, err := enqueueAsyncFallback(queuer, "fn1", r)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if callID == "" {
+		t.Fatal("expected a non-empty call id")
+	}
+
+	if len(queuer.Requests) != 1 {
+		t.Fatalf("expected 1 queued request, got %d", len(queuer.Requests))
+	}
+
+	queued := queuer.Requests[0]
+	if queued.Function != "fn1" {
+		t.Errorf("expected Function fn1, got %s", queued.Function)
+	}
+	if string(queued.Body) != body {
+		t.Errorf("expected Body %q, got %q", body, string(queued.Body))
+	}
+	if queued.QueryString != "foo=bar" {
+		t.Errorf("expected QueryString foo=bar, got %s", queued.QueryString)
+	}
+	if queued.CallbackURL != "http://example.com/callback" {
+		t.Errorf("expected CallbackURL to be carried through, got %s", queued.CallbackURL)
+	}
+	if queued.CallID != callID {
+		t.Errorf("expected queued CallID to match returned callID")
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright (c) OpenFaaS Author(s). All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package handlers
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// BackoffStrategy computes how long to wait before the next poll attempt
+// while scaling a function from zero
+type BackoffStrategy interface {
+	// Next returns the delay to wait after the given zero-based attempt
+	// has not yet found a replica, before the next attempt is made.
+	Next(attempt int) time.Duration
+}
+
+// ConstantBackoff waits the same Interval between every attempt. This is
+// the default, matching the original fixed-delay polling behaviour.
+type ConstantBackoff struct {
+	Interval time.Duration
+}
+
+// Next implements BackoffStrategy
+func (b ConstantBackoff) Next(attempt int) time.Duration {
+	return b.Interval
+}
+
+// LinearBackoff grows the delay linearly with the attempt number
+type LinearBackoff struct {
+	Interval time.Duration
+}
+
+// Next implements BackoffStrategy
+func (b LinearBackoff) Next(attempt int) time.Duration {
+	return time.Duration(attempt+1) * b.Interval
+}
+
+// ExponentialBackoff multiplies the delay by Factor for every attempt,
+// capped at Max once set.
+type ExponentialBackoff struct {
+	Interval time.Duration
+	Factor   float64
+	Max      time.Duration
+}
+
+// Next implements BackoffStrategy
+func (b ExponentialBackoff) Next(attempt int) time.Duration {
+	factor := b.Factor
+	if factor <= 0 {
+		factor = 2
+	}
+
+	delay := time.Duration(float64(b.Interval) * math.Pow(factor, float64(attempt)))
+	if b.Max > 0 && delay > b.Max {
+		delay = b.Max
+	}
+
+	return delay
+}
+
+// DecorrelatedJitterBackoff implements the "decorrelated jitter" algorithm
+// described by the AWS Architecture Blog
+type DecorrelatedJitterBackoff struct {
+	Base time.Duration
+	Max  time.Duration
+
+	mu   sync.Mutex
+	prev time.Duration
+}
+
+// Next implements BackoffStrategy
+func (b *DecorrelatedJitterBackoff) Next(attempt int) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	prev := b.prev
+	if prev == 0 {
+		prev = b.Base
+	}
+
+	upper := float64(prev) * 3
+	delay := time.Duration(float64(b.Base) + rand.Float64()*(upper-float64(b.Base)))
+	if b.Max > 0 && delay > b.Max {
+		delay = b.Max
+	}
+
+	b.prev = delay
+	return delay
+}
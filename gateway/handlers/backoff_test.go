@@ -0,0 +1,107 @@
+// Copyright (c) OpenFaaS Author(s). All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package handlers
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_ConstantBackoff_Next(t *testing.T) {
+	b := ConstantBackoff{Interval: 5 * time.Second}
+
+	for attempt := 0; attempt < 3; attempt++ {
+		if got := b.Next(attempt); got != 5*time.Second {
+			t.Errorf("attempt %d: expected 5s, got %s", attempt, got)
+		}
+	}
+}
+
+func Test_LinearBackoff_Next(t *testing.T) {
+	b := LinearBackoff{Interval: time.Second}
+
+	cases := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, time.Second},
+		{1, 2 * time.Second},
+		{2, 3 * time.Second},
+	}
+
+	for _, c := range cases {
+		if got := b.Next(c.attempt); got != c.want {
+			t.Errorf("attempt %d: expected %s, got %s", c.attempt, c.want, got)
+		}
+	}
+}
+
+func Test_ExponentialBackoff_Next(t *testing.T) {
+	b := ExponentialBackoff{Interval: time.Second, Factor: 2, Max: 5 * time.Second}
+
+	cases := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, time.Second},
+		{1, 2 * time.Second},
+		{2, 4 * time.Second},
+		{3, 5 * time.Second}, // capped by Max (would otherwise be 8s)
+	}
+
+	for _, c := range cases {
+		if got := b.Next(c.attempt); got != c.want {
+			t.Errorf("attempt %d: expected %s, got %s", c.attempt, c.want, got)
+		}
+	}
+}
+
+func Test_ExponentialBackoff_DefaultsFactorToTwo(t *testing.T) {
+	b := ExponentialBackoff{Interval: time.Second}
+
+	if got := b.Next(2); got != 4*time.Second {
+		t.Errorf("expected default factor of 2 to give 4s at attempt 2, got %s", got)
+	}
+}
+
+func Test_DecorrelatedJitterBackoff_Next_WithinBounds(t *testing.T) {
+	b := &DecorrelatedJitterBackoff{Base: 100 * time.Millisecond, Max: time.Second}
+
+	prev := b.Base
+	for i := 0; i < 50; i++ {
+		delay := b.Next(i)
+
+		if delay < b.Base {
+			t.Fatalf("attempt %d: delay %s below Base %s", i, delay, b.Base)
+		}
+		if delay > b.Max {
+			t.Fatalf("attempt %d: delay %s exceeds Max %s", i, delay, b.Max)
+		}
+
+		upper := time.Duration(float64(prev) * 3)
+		if upper > b.Max {
+			upper = b.Max
+		}
+		if delay > upper {
+			t.Fatalf("attempt %d: delay %s exceeds 3x previous delay %s", i, delay, prev)
+		}
+
+		prev = delay
+	}
+}
+
+func Test_DecorrelatedJitterBackoff_Next_ConcurrentSafe(t *testing.T) {
+	b := &DecorrelatedJitterBackoff{Base: time.Millisecond, Max: 100 * time.Millisecond}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(attempt int) {
+			defer wg.Done()
+			b.Next(attempt)
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,163 @@
+// Copyright (c) OpenFaaS Author(s). All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package handlers
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache stores the last known replica status for functions, keyed by
+// function name. The default implementation, FunctionCache, is an
+// in-process map; a Redis-backed implementation lives alongside it so
+// that HA gateways with multiple replicas can share one view of which
+// functions are already warm.
+type Cache interface {
+	// Get returns the cached response for functionName. hit is false if
+	// there was no entry, or the entry has expired.
+	Get(functionName string) (response ServiceQueryResponse, hit bool)
+
+	// Set unconditionally stores response for functionName
+	Set(functionName string, response ServiceQueryResponse)
+
+	// Delete removes any cached entry for functionName
+	Delete(functionName string)
+
+	// SetIfNewer stores response for functionName unless an existing,
+	// unexpired entry reports a higher AvailableReplicas count, so that a
+	// slower gateway's stale 0-replica write cannot clobber a fresher
+	// count just written by a peer. Returns true if the store happened.
+	SetIfNewer(functionName string, response ServiceQueryResponse) bool
+
+	// Claim attempts to become the sole owner of scaling functionName up
+	// from zero, for the given ttl. Returns true if the caller won the
+	// claim and must perform the scale-up; false means another caller
+	// (possibly on a different gateway) already holds it.
+	Claim(functionName string, ttl time.Duration) bool
+
+	// Release gives up a claim obtained via Claim
+	Release(functionName string)
+}
+
+// FunctionCache is the default, per-process implementation of Cache
+type FunctionCache struct {
+	Cache  map[string]*FunctionMeta
+	Sync   sync.Mutex
+	Expiry time.Duration
+
+	claims map[string]bool
+}
+
+// FunctionMeta holds the last time a query was made along with the response
+type FunctionMeta struct {
+	LastRefresh time.Time
+	ServiceQueryResponse
+}
+
+// Expired finds out whether the cache item has expired with the given expiry
+func (fm *FunctionMeta) Expired(expiry time.Duration) bool {
+	return time.Now().After(fm.LastRefresh.Add(expiry))
+}
+
+// Set replica count for functionName
+func (fc *FunctionCache) Set(functionName string, response ServiceQueryResponse) {
+	fc.Sync.Lock()
+	defer fc.Sync.Unlock()
+
+	if fc.Cache == nil {
+		fc.Cache = make(map[string]*FunctionMeta)
+	}
+
+	fc.Cache[functionName] = &FunctionMeta{
+		LastRefresh:          time.Now(),
+		ServiceQueryResponse: response,
+	}
+}
+
+// Get replica count for functionName, hit is false if there was no entry
+// or the entry has expired
+func (fc *FunctionCache) Get(functionName string) (response ServiceQueryResponse, hit bool) {
+	fc.Sync.Lock()
+	defer fc.Sync.Unlock()
+
+	entry, exists := fc.Cache[functionName]
+	if !exists {
+		return ServiceQueryResponse{}, false
+	}
+
+	return entry.ServiceQueryResponse, !entry.Expired(fc.Expiry)
+}
+
+// Delete removes any cached entry for functionName
+func (fc *FunctionCache) Delete(functionName string) {
+	fc.Sync.Lock()
+	defer fc.Sync.Unlock()
+
+	delete(fc.Cache, functionName)
+}
+
+// SetIfNewer stores response for functionName unless an existing,
+// unexpired entry already reports a higher AvailableReplicas count.
+func (fc *FunctionCache) SetIfNewer(functionName string, response ServiceQueryResponse) bool {
+	fc.Sync.Lock()
+	defer fc.Sync.Unlock()
+
+	if fc.Cache == nil {
+		fc.Cache = make(map[string]*FunctionMeta)
+	}
+
+	entry, exists := fc.Cache[functionName]
+	if exists && !entry.Expired(fc.Expiry) && entry.AvailableReplicas > response.AvailableReplicas {
+		return false
+	}
+
+	fc.Cache[functionName] = &FunctionMeta{
+		LastRefresh:          time.Now(),
+		ServiceQueryResponse: response,
+	}
+
+	return true
+}
+
+// Claim acquires the in-process claim on functionName. ttl is unused here
+// since a single process already serialises scale-ups via scaleUpGroup;
+// it exists so Claim has the same signature as a distributed Cache.
+func (fc *FunctionCache) Claim(functionName string, ttl time.Duration) bool {
+	fc.Sync.Lock()
+	defer fc.Sync.Unlock()
+
+	if fc.claims == nil {
+		fc.claims = make(map[string]bool)
+	}
+
+	if fc.claims[functionName] {
+		return false
+	}
+
+	fc.claims[functionName] = true
+	return true
+}
+
+// Release gives up a claim obtained via Claim
+func (fc *FunctionCache) Release(functionName string) {
+	fc.Sync.Lock()
+	defer fc.Sync.Unlock()
+
+	delete(fc.claims, functionName)
+}
+
+// getServiceName extracts the function/service name from the first path
+// segment after any /function/ prefix of a gateway request, e.g.
+// /function/foo/bar => foo
+func getServiceName(urlValue string) string {
+	path := strings.TrimPrefix(urlValue, "/function/")
+	path = strings.TrimPrefix(path, "/")
+
+	if ix := strings.Index(path, "/"); ix > -1 {
+		return path[:ix]
+	}
+
+	return path
+}
@@ -0,0 +1,71 @@
+// Copyright (c) OpenFaaS Author(s). All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_FunctionCache_SetIfNewer_WinsOnHigherCount(t *testing.T) {
+	fc := &FunctionCache{Expiry: time.Minute}
+
+	fc.Set("fn1", ServiceQueryResponse{AvailableReplicas: 1})
+
+	ok := fc.SetIfNewer("fn1", ServiceQueryResponse{AvailableReplicas: 2})
+	if !ok {
+		t.Fatal("expected SetIfNewer to report a store")
+	}
+
+	response, hit := fc.Get("fn1")
+	if !hit || response.AvailableReplicas != 2 {
+		t.Fatalf("expected AvailableReplicas 2, got %+v (hit=%v)", response, hit)
+	}
+}
+
+func Test_FunctionCache_SetIfNewer_StaleWriteLoses(t *testing.T) {
+	fc := &FunctionCache{Expiry: time.Minute}
+
+	fc.Set("fn1", ServiceQueryResponse{AvailableReplicas: 3})
+
+	ok := fc.SetIfNewer("fn1", ServiceQueryResponse{AvailableReplicas: 1})
+	if ok {
+		t.Fatal("expected SetIfNewer to reject a lower AvailableReplicas count")
+	}
+
+	response, hit := fc.Get("fn1")
+	if !hit || response.AvailableReplicas != 3 {
+		t.Fatalf("expected stale write to leave AvailableReplicas at 3, got %+v (hit=%v)", response, hit)
+	}
+}
+
+func Test_FunctionCache_SetIfNewer_WinsWhenExpired(t *testing.T) {
+	fc := &FunctionCache{Expiry: time.Millisecond}
+
+	fc.Set("fn1", ServiceQueryResponse{AvailableReplicas: 3})
+	time.Sleep(5 * time.Millisecond)
+
+	ok := fc.SetIfNewer("fn1", ServiceQueryResponse{AvailableReplicas: 1})
+	if !ok {
+		t.Fatal("expected SetIfNewer to overwrite an expired entry regardless of count")
+	}
+}
+
+func Test_FunctionCache_Claim_SerialisesOwnership(t *testing.T) {
+	fc := &FunctionCache{}
+
+	if !fc.Claim("fn1", time.Minute) {
+		t.Fatal("expected first Claim to succeed")
+	}
+
+	if fc.Claim("fn1", time.Minute) {
+		t.Fatal("expected second concurrent Claim to fail")
+	}
+
+	fc.Release("fn1")
+
+	if !fc.Claim("fn1", time.Minute) {
+		t.Fatal("expected Claim to succeed again after Release")
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright (c) OpenFaaS Author(s). All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package handlers
+
+import (
+	"log"
+	"math"
+	"sync"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	inflightPerReplica = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "gateway",
+		Subsystem: "function",
+		Name:      "inflight_per_replica",
+		Help:      "Inflight request count divided by available replicas for a function",
+	}, []string{"function_name"})
+
+	scaleOutDecisionsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "gateway",
+		Subsystem: "function",
+		Name:      "scale_out_decisions_total",
+		Help:      "Number of times a function was scaled out due to inflight concurrency exceeding its target",
+	}, []string{"function_name"})
+)
+
+func init() {
+	prometheus.MustRegister(inflightPerReplica, scaleOutDecisionsTotal)
+}
+
+// inflightScaler tracks in-flight requests per function and asks
+// ServiceQuery to scale out once the count per available replica
+// exceeds a configured target
+type inflightScaler struct {
+	mu      sync.Mutex
+	counts  map[string]*int64
+	scaling map[string]bool
+}
+
+// track increments the in-flight count for functionName and returns a
+// func that must be called once the request has finished to decrement it
+// again.
+func (s *inflightScaler) track(functionName string) (int64, func()) {
+	s.mu.Lock()
+	counter, ok := s.counts[functionName]
+	if !ok {
+		if s.counts == nil {
+			s.counts = make(map[string]*int64)
+		}
+		counter = new(int64)
+		s.counts[functionName] = counter
+	}
+	s.mu.Unlock()
+
+	inflight := atomic.AddInt64(counter, 1)
+
+	return inflight, func() {
+		atomic.AddInt64(counter, -1)
+	}
+}
+
+// maybeScaleOut calls SetReplicas with a proportionally larger replica
+// count, bounded by maxReplicas, once inflight per availableReplicas
+// exceeds config.TargetInflightPerReplica. At most one scale-out call
+// runs at a time per function.
+func (s *inflightScaler) maybeScaleOut(config ScalingConfig, functionName string, inflight int64, availableReplicas, maxReplicas uint64) {
+	if config.TargetInflightPerReplica == 0 || availableReplicas == 0 {
+		return
+	}
+
+	ratio := float64(inflight) / float64(availableReplicas)
+	inflightPerReplica.WithLabelValues(functionName).Set(ratio)
+
+	if ratio <= float64(config.TargetInflightPerReplica) {
+		return
+	}
+
+	s.mu.Lock()
+	if s.scaling == nil {
+		s.scaling = make(map[string]bool)
+	}
+	if s.scaling[functionName] {
+		s.mu.Unlock()
+		return
+	}
+	s.scaling[functionName] = true
+	s.mu.Unlock()
+
+	desired := uint64(math.Ceil(float64(inflight) / float64(config.TargetInflightPerReplica)))
+	if maxReplicas > 0 && desired > maxReplicas {
+		desired = maxReplicas
+	}
+
+	go func() {
+		defer func() {
+			s.mu.Lock()
+			delete(s.scaling, functionName)
+			s.mu.Unlock()
+		}()
+
+		if desired <= availableReplicas {
+			return
+		}
+
+		log.Printf("[Scale] function=%s %d => %d requested (inflight=%d, target=%d)", functionName, availableReplicas, desired, inflight, config.TargetInflightPerReplica)
+
+		if err := config.ServiceQuery.SetReplicas(functionName, desired); err != nil {
+			log.Printf("[Scale] function=%s unable to scale out: %s", functionName, err)
+			return
+		}
+
+		scaleOutDecisionsTotal.WithLabelValues(functionName).Inc()
+	}()
+}
@@ -0,0 +1,104 @@
+// Copyright (c) OpenFaaS Author(s). All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package handlers
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeServiceQuery is a ServiceQuery that records SetReplicas calls instead
+// of talking to a real provider.
+type fakeServiceQuery struct {
+	mu             sync.Mutex
+	setReplicas    []uint64
+	replicasCalled chan struct{}
+}
+
+func (f *fakeServiceQuery) GetReplicas(service string) (ServiceQueryResponse, error) {
+	return ServiceQueryResponse{}, nil
+}
+
+func (f *fakeServiceQuery) SetReplicas(service string, count uint64) error {
+	f.mu.Lock()
+	f.setReplicas = append(f.setReplicas, count)
+	f.mu.Unlock()
+
+	if f.replicasCalled != nil {
+		f.replicasCalled <- struct{}{}
+	}
+
+	return nil
+}
+
+func (f *fakeServiceQuery) calls() []uint64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	out := make([]uint64, len(f.setReplicas))
+	copy(out, f.setReplicas)
+	return out
+}
+
+func Test_inflightScaler_maybeScaleOut_ScalesWhenTargetExceeded(t *testing.T) {
+	query := &fakeServiceQuery{replicasCalled: make(chan struct{}, 1)}
+	config := ScalingConfig{
+		ServiceQuery:             query,
+		TargetInflightPerReplica: 2,
+	}
+
+	s := inflightScaler{}
+	s.maybeScaleOut(config, "fn1", 5, 1, 10)
+
+	select {
+	case <-query.replicasCalled:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for SetReplicas")
+	}
+
+	calls := query.calls()
+	if len(calls) != 1 || calls[0] != 3 {
+		t.Fatalf("expected a single SetReplicas(3) call, got %v", calls)
+	}
+}
+
+func Test_inflightScaler_maybeScaleOut_BoundedByMaxReplicas(t *testing.T) {
+	query := &fakeServiceQuery{replicasCalled: make(chan struct{}, 1)}
+	config := ScalingConfig{
+		ServiceQuery:             query,
+		TargetInflightPerReplica: 2,
+	}
+
+	s := inflightScaler{}
+	s.maybeScaleOut(config, "fn1", 20, 1, 5)
+
+	select {
+	case <-query.replicasCalled:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for SetReplicas")
+	}
+
+	calls := query.calls()
+	if len(calls) != 1 || calls[0] != 5 {
+		t.Fatalf("expected SetReplicas bounded to maxReplicas 5, got %v", calls)
+	}
+}
+
+func Test_inflightScaler_maybeScaleOut_NoOpBelowTarget(t *testing.T) {
+	query := &fakeServiceQuery{}
+	config := ScalingConfig{
+		ServiceQuery:             query,
+		TargetInflightPerReplica: 10,
+	}
+
+	s := inflightScaler{}
+	s.maybeScaleOut(config, "fn1", 5, 1, 10)
+
+	time.Sleep(10 * time.Millisecond)
+
+	if calls := query.calls(); len(calls) != 0 {
+		t.Fatalf("expected no SetReplicas calls, got %v", calls)
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright (c) OpenFaaS Author(s). All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package handlers
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/singleflight"
+)
+
+// coalescedColdStarts counts requests that avoided issuing their own
+// SetReplicas/poll loop because another in-flight request was already
+// scaling the same function from zero.
+var coalescedColdStarts = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: "gateway",
+	Subsystem: "function",
+	Name:      "coalesced_cold_starts_total",
+	Help:      "Number of cold-start scale-up requests coalesced onto an in-flight scale-up for the same function",
+})
+
+func init() {
+	prometheus.MustRegister(coalescedColdStarts)
+}
+
+// scaleUpGroup coalesces concurrent scale-from-zero attempts for the
+// same function, so only one goroutine calls SetReplicas and polls
+type scaleUpGroup struct {
+	group singleflight.Group
+}
+
+// Do runs fn for functionName if no scale-up is already in flight for it,
+// otherwise waits for the in-flight call and shares its result. Returns
+// ctx.Err() if ctx is cancelled before a shared result is available.
+func (g *scaleUpGroup) Do(ctx context.Context, functionName string, fn func() (ServiceQueryResponse, error)) (ServiceQueryResponse, error) {
+	// executed distinguishes the leader (whose fn actually ran) from
+	// followers sharing its result, so only followers count as coalesced.
+	executed := false
+	resChan := g.group.DoChan(functionName, func() (interface{}, error) {
+		executed = true
+		return fn()
+	})
+
+	select {
+	case res := <-resChan:
+		if res.Err != nil {
+			return ServiceQueryResponse{}, res.Err
+		}
+
+		if res.Shared && !executed {
+			coalescedColdStarts.Inc()
+		}
+
+		return res.Val.(ServiceQueryResponse), nil
+	case <-ctx.Done():
+		return ServiceQueryResponse{}, ctx.Err()
+	}
+}
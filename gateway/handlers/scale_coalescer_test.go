@@ -0,0 +1,78 @@
+// Copyright (c) OpenFaaS Author(s). All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package handlers
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_scaleUpGroup_CoalescesConcurrentCallers(t *testing.T) {
+	g := scaleUpGroup{}
+
+	var calls int64
+	fn := func() (ServiceQueryResponse, error) {
+		atomic.AddInt64(&calls, 1)
+		time.Sleep(20 * time.Millisecond)
+		return ServiceQueryResponse{AvailableReplicas: 1}, nil
+	}
+
+	const callers = 10
+	var wg sync.WaitGroup
+	wg.Add(callers)
+
+	for i := 0; i < callers; i++ {
+		go func() {
+			defer wg.Done()
+			res, err := g.Do(context.Background(), "fn1", fn)
+			if err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+			if res.AvailableReplicas != 1 {
+				t.Errorf("expected AvailableReplicas 1, got %d", res.AvailableReplicas)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("expected fn to run once, ran %d times", calls)
+	}
+}
+
+func Test_scaleUpGroup_Do_ContextCancelled(t *testing.T) {
+	g := scaleUpGroup{}
+
+	block := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		g.Do(context.Background(), "fn1", func() (ServiceQueryResponse, error) {
+			<-block
+			return ServiceQueryResponse{}, nil
+		})
+		close(done)
+	}()
+
+	// give the leader a chance to register the in-flight call
+	time.Sleep(5 * time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := g.Do(ctx, "fn1", func() (ServiceQueryResponse, error) {
+		<-block
+		return ServiceQueryResponse{}, nil
+	})
+	if err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	close(block)
+	<-done
+}
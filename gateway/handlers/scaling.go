@@ -4,12 +4,20 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"time"
+
+	"github.com/openfaas/faas/gateway/queue"
 )
 
+// ErrScaleUpTimedOut is returned by scaleFromZero when a function does not
+// report an available replica before MaxPollCount or MaxScaleUpDuration is
+// exhausted.
+var ErrScaleUpTimedOut = errors.New("scale up did not complete in time")
+
 // ScalingConfig for scaling behaviours
 type ScalingConfig struct {
 	// MaxPollCount attempts to query a function before giving up
@@ -23,6 +31,35 @@ type ScalingConfig struct {
 
 	// ServiceQuery queries available/ready replicas for function
 	ServiceQuery ServiceQuery
+
+	// Backoff controls the delay between poll attempts while scaling a
+	// function from zero. Defaults to ConstantBackoff{FunctionPollInterval}
+	// when unset.
+	Backoff BackoffStrategy
+
+	// MaxScaleUpDuration is an overall deadline for a single scale-from-zero
+	// attempt, independent of MaxPollCount, so slow-scaling workloads (e.g.
+	// GPU images pulling multi-GB layers) can be accommodated without
+	// setting a huge poll count with a tiny interval. Zero means no deadline.
+	MaxScaleUpDuration time.Duration
+
+	// Cache stores known-warm function status. Defaults to a per-process
+	// FunctionCache when unset; set this to a shared implementation (e.g.
+	// a Redis-backed one) so that multiple HA gateway replicas benefit
+	// from each other's scale-up work.
+	Cache Cache
+
+	// Queuer, when set, lets the scaling handler enqueue a request for
+	// later, asynchronous delivery instead of returning an error when a
+	// function does not scale up in time. Requests only take this path if
+	// they opt in with an X-Callback-Url or X-Async header.
+	Queuer queue.RequestQueuer
+
+	// TargetInflightPerReplica, when non-zero, enables concurrency-aware
+	// scale-out: once inflight requests per available replica for a
+	// function exceeds this value, the handler asks ServiceQuery to scale
+	// the function out proportionally, bounded by MaxReplicas.
+	TargetInflightPerReplica uint64
 }
 
 // MakeScalingHandler creates handler which can scale a function from
@@ -31,17 +68,31 @@ type ScalingConfig struct {
 // amount of attempts / queries then next will not be invoked and a status
 // will be returned to the client.
 func MakeScalingHandler(next http.HandlerFunc, config ScalingConfig) http.HandlerFunc {
-	cache := FunctionCache{
-		Cache:  make(map[string]*FunctionMeta),
-		Expiry: config.CacheExpiry,
+	cache := config.Cache
+	if cache == nil {
+		cache = &FunctionCache{
+			Cache:  make(map[string]*FunctionMeta),
+			Expiry: config.CacheExpiry,
+		}
 	}
+	scaleUp := scaleUpGroup{}
+	inflight := inflightScaler{}
 
 	return func(w http.ResponseWriter, r *http.Request) {
 
 		functionName := getServiceName(r.URL.String())
 
-		if serviceQueryResponse, hit := cache.Get(functionName); hit && serviceQueryResponse.AvailableReplicas > 0 {
+		serve := func(available, max uint64) {
+			inflightCount, release := inflight.track(functionName)
+			defer release()
+
+			inflight.maybeScaleOut(config, functionName, inflightCount, available, max)
+
 			next.ServeHTTP(w, r)
+		}
+
+		if serviceQueryResponse, hit := cache.Get(functionName); hit && serviceQueryResponse.AvailableReplicas > 0 {
+			serve(serviceQueryResponse.AvailableReplicas, serviceQueryResponse.MaxReplicas)
 			return
 		}
 
@@ -60,47 +111,115 @@ func MakeScalingHandler(next http.HandlerFunc, config ScalingConfig) http.Handle
 		cache.Set(functionName, queryResponse)
 
 		if queryResponse.AvailableReplicas == 0 {
-			minReplicas := uint64(1)
-			if queryResponse.MinReplicas > 0 {
-				minReplicas = queryResponse.MinReplicas
+			_, err = scaleUp.Do(r.Context(), functionName, func() (ServiceQueryResponse, error) {
+				// Only the leader of the scaleUpGroup reaches this closure,
+				// so it alone issues SetReplicas/poll and updates the cache.
+				return scaleFromZero(config, cache, functionName, queryResponse)
+			})
+
+			if err != nil {
+				if errors.Is(err, ErrScaleUpTimedOut) && config.Queuer != nil && wantsAsyncFallback(r) {
+					callID, queueErr := enqueueAsyncFallback(config.Queuer, functionName, r)
+					if queueErr == nil {
+						w.Header().Set(callIDHeader, callID)
+						w.WriteHeader(http.StatusAccepted)
+						return
+					}
+
+					log.Printf("[Scale] function=%s unable to queue async fallback: %s", functionName, queueErr)
+				}
+
+				errStr := fmt.Sprintf("error: %s", err.Error())
+				log.Printf(errStr)
+
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte(errStr))
+				return
 			}
 
-			log.Printf("[Scale] function=%s 0 => %d requested", functionName, minReplicas)
-			scalingStartTime := time.Now()
+			// Followers re-check the cache after being released, rather than
+			// trusting the shared result directly, in case a newer update
+			// has since landed from another leader.
+			queryResponse, _ = cache.Get(functionName)
 
-			err := config.ServiceQuery.SetReplicas(functionName, minReplicas)
-			if err != nil {
-				errStr := fmt.Errorf("unable to scale function [%s], err: %s", functionName, err)
-				log.Printf(errStr.Error())
+			if queryResponse.AvailableReplicas == 0 {
+				errStr := fmt.Sprintf("function %s did not become ready after scaling", functionName)
+				log.Printf(errStr)
 
 				w.WriteHeader(http.StatusInternalServerError)
-				w.Write([]byte(errStr.Error()))
+				w.Write([]byte(errStr))
 				return
 			}
+		}
 
-			for i := 0; i < int(config.MaxPollCount); i++ {
-				queryResponse, err := config.ServiceQuery.GetReplicas(functionName)
-				cache.Set(functionName, queryResponse)
+		serve(queryResponse.AvailableReplicas, queryResponse.MaxReplicas)
+	}
+}
 
-				if err != nil {
-					errStr := fmt.Sprintf("error: %s", err.Error())
-					log.Printf(errStr)
+// scaleFromZero polls until functionName reports an available replica or
+// config.MaxPollCount is exhausted. It is only ever invoked by the
+// in-process leader of a scaleUpGroup, so concurrent callers on this
+// gateway share this single attempt. It additionally claims functionName
+// in cache before calling SetReplicas: if another gateway replica already
+// holds that claim, this call skips SetReplicas entirely and just polls
+// the shared cache for the other replica's result, so the two gateways
+// still only issue one SetReplicas between them.
+func scaleFromZero(config ScalingConfig, cache Cache, functionName string, current ServiceQueryResponse) (ServiceQueryResponse, error) {
+	minReplicas := uint64(1)
+	if current.MinReplicas > 0 {
+		minReplicas = current.MinReplicas
+	}
 
-					w.WriteHeader(http.StatusInternalServerError)
-					w.Write([]byte(errStr))
-					return
-				}
+	backoff := config.Backoff
+	if backoff == nil {
+		backoff = ConstantBackoff{Interval: config.FunctionPollInterval}
+	}
 
-				if queryResponse.AvailableReplicas > 0 {
-					scalingDuration := time.Since(scalingStartTime)
-					log.Printf("[Scale] function=%s 0 => %d successful - %f seconds", functionName, queryResponse.AvailableReplicas, scalingDuration.Seconds())
-					break
-				}
+	claimTTL := config.MaxScaleUpDuration
+	if claimTTL <= 0 {
+		claimTTL = time.Duration(config.MaxPollCount) * config.FunctionPollInterval
+	}
+
+	scalingStartTime := time.Now()
+	owner := cache.Claim(functionName, claimTTL)
 
-				time.Sleep(config.FunctionPollInterval)
+	if owner {
+		defer cache.Release(functionName)
+
+		log.Printf("[Scale] function=%s 0 => %d requested", functionName, minReplicas)
+		if err := config.ServiceQuery.SetReplicas(functionName, minReplicas); err != nil {
+			return ServiceQueryResponse{}, fmt.Errorf("unable to scale function [%s], err: %s", functionName, err)
+		}
+	} else {
+		log.Printf("[Scale] function=%s scale-up already claimed by another gateway, waiting", functionName)
+	}
+
+	queryResponse := current
+	for i := 0; i < int(config.MaxPollCount); i++ {
+		if config.MaxScaleUpDuration > 0 && time.Since(scalingStartTime) > config.MaxScaleUpDuration {
+			return ServiceQueryResponse{}, ErrScaleUpTimedOut
+		}
+
+		if owner {
+			var err error
+			queryResponse, err = config.ServiceQuery.GetReplicas(functionName)
+			if err != nil {
+				return ServiceQueryResponse{}, err
 			}
+
+			cache.SetIfNewer(functionName, queryResponse)
+		} else if cached, hit := cache.Get(functionName); hit {
+			queryResponse = cached
+		}
+
+		if queryResponse.AvailableReplicas > 0 {
+			scalingDuration := time.Since(scalingStartTime)
+			log.Printf("[Scale] function=%s 0 => %d successful - %f seconds", functionName, queryResponse.AvailableReplicas, scalingDuration.Seconds())
+			return queryResponse, nil
 		}
 
-		next.ServeHTTP(w, r)
+		time.Sleep(backoff.Next(i))
 	}
+
+	return ServiceQueryResponse{}, ErrScaleUpTimedOut
 }
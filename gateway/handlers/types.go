@@ -0,0 +1,34 @@
+// Copyright (c) OpenFaaS Author(s). All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package handlers
+
+// ServiceQuery provides interface for replica counts for functions
+type ServiceQuery interface {
+	// GetReplicas queries any provider for function replica counts
+	GetReplicas(service string) (response ServiceQueryResponse, err error)
+
+	// SetReplicas sets the required replicas for a function
+	SetReplicas(service string, count uint64) error
+}
+
+// ServiceQueryResponse response from querying a function status
+type ServiceQueryResponse struct {
+	Replicas          uint64
+	MaxReplicas       uint64
+	MinReplicas       uint64
+	ScalingInProgress bool
+	AvailableReplicas uint64
+
+	// Usage is only populated by ServiceQuery implementations that support
+	// reporting it, e.g. ExternalServiceQuery with IncludeUsage set
+	Usage FunctionUsage
+}
+
+// FunctionUsage carries CPU/RAM/inflight metrics for a function, for
+// ServiceQuery implementations able to report them
+type FunctionUsage struct {
+	CPU              float64 `json:"cpu,omitempty"`
+	RAM              float64 `json:"ram,omitempty"`
+	InflightRequests int64   `json:"inflightRequests,omitempty"`
+}
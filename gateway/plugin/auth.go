@@ -0,0 +1,24 @@
+// Copyright (c) OpenFaaS Author(s). All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package plugin
+
+import "net/http"
+
+// AuthInjector signs or otherwise decorates an outbound *http.Request
+// before it is sent to an external provider
+type AuthInjector interface {
+	Inject(r *http.Request) error
+}
+
+// BearerAuthInjector adds a static "Authorization: Bearer <token>" header,
+// suitable for providers that accept a long-lived service token.
+type BearerAuthInjector struct {
+	Token string
+}
+
+// Inject adds the bearer token to the outbound request
+func (b *BearerAuthInjector) Inject(r *http.Request) error {
+	r.Header.Set("Authorization", "Bearer "+b.Token)
+	return nil
+}
@@ -0,0 +1,159 @@
+// Copyright (c) OpenFaaS Author(s). All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+// Package plugin provides out-of-process implementations of the scaling
+// interfaces found in gateway/handlers
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/openfaas/faas/gateway/handlers"
+)
+
+// externalFunctionStatus is the wire format returned by
+// GET /function/{name} on the external provider
+type externalFunctionStatus struct {
+	Replicas          uint64                 `json:"replicas"`
+	MaxReplicas       uint64                 `json:"maxReplicas"`
+	MinReplicas       uint64                 `json:"minReplicas"`
+	ScalingInProgress bool                   `json:"scalingInProgress"`
+	AvailableReplicas uint64                 `json:"availableReplicas"`
+	Usage             handlers.FunctionUsage `json:"usage,omitempty"`
+}
+
+// scaleFunctionRequest is the wire format posted to
+// POST /system/scale-function/{name}
+type scaleFunctionRequest struct {
+	ServiceName string `json:"serviceName"`
+	Replicas    uint64 `json:"replicas"`
+}
+
+// ExternalServiceQuery is a handlers.ServiceQuery that delegates scaling
+// decisions to an external, provider-compliant HTTP service
+type ExternalServiceQuery struct {
+	// URL is the base address of the external provider, e.g.
+	// http://provider.openfaas:8081
+	URL *url.URL
+
+	// Client performs the outbound HTTP calls
+	Client *http.Client
+
+	// Auth signs outbound requests, may be nil if the provider requires
+	// no authentication
+	Auth AuthInjector
+
+	// IncludeUsage asks the provider to embed CPU/RAM/inflight usage
+	// metrics in its GetReplicas response
+	IncludeUsage bool
+}
+
+// NewExternalServiceQuery creates an ExternalServiceQuery pointed at the
+// given provider URL, with a default timeout suitable for a scaling poll
+func NewExternalServiceQuery(providerURL url.URL, auth AuthInjector) ExternalServiceQuery {
+	return ExternalServiceQuery{
+		URL: &providerURL,
+		Client: &http.Client{
+			Timeout: 3 * time.Second,
+		},
+		Auth: auth,
+	}
+}
+
+// GetReplicas queries the external provider for the current replica
+// count of the named function
+func (s ExternalServiceQuery) GetReplicas(functionName string) (handlers.ServiceQueryResponse, error) {
+	getURL := fmt.Sprintf("%s/function/%s", strings.TrimRight(s.URL.String(), "/"), url.PathEscape(functionName))
+
+	req, err := http.NewRequest(http.MethodGet, getURL, nil)
+	if err != nil {
+		return handlers.ServiceQueryResponse{}, err
+	}
+
+	if s.IncludeUsage {
+		q := req.URL.Query()
+		q.Set("usage", "true")
+		req.URL.RawQuery = q.Encode()
+	}
+
+	if s.Auth != nil {
+		if err := s.Auth.Inject(req); err != nil {
+			return handlers.ServiceQueryResponse{}, fmt.Errorf("unable to authenticate request to provider: %s", err)
+		}
+	}
+
+	res, err := s.Client.Do(req)
+	if err != nil {
+		return handlers.ServiceQueryResponse{}, err
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return handlers.ServiceQueryResponse{}, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return handlers.ServiceQueryResponse{}, fmt.Errorf("provider returned status %d for function %s: %s", res.StatusCode, functionName, string(body))
+	}
+
+	status := externalFunctionStatus{}
+	if err := json.Unmarshal(body, &status); err != nil {
+		return handlers.ServiceQueryResponse{}, fmt.Errorf("unable to parse provider response for function %s: %s", functionName, err)
+	}
+
+	return handlers.ServiceQueryResponse{
+		Replicas:          status.Replicas,
+		MaxReplicas:       status.MaxReplicas,
+		MinReplicas:       status.MinReplicas,
+		ScalingInProgress: status.ScalingInProgress,
+		AvailableReplicas: status.AvailableReplicas,
+		Usage:             status.Usage,
+	}, nil
+}
+
+// SetReplicas requests that the external provider scale the named
+// function to the given replica count
+func (s ExternalServiceQuery) SetReplicas(functionName string, count uint64) error {
+	scaleURL := fmt.Sprintf("%s/system/scale-function/%s", strings.TrimRight(s.URL.String(), "/"), url.PathEscape(functionName))
+
+	body, err := json.Marshal(scaleFunctionRequest{
+		ServiceName: functionName,
+		Replicas:    count,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, scaleURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if s.Auth != nil {
+		if err := s.Auth.Inject(req); err != nil {
+			return fmt.Errorf("unable to authenticate request to provider: %s", err)
+		}
+	}
+
+	res, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusAccepted {
+		respBody, _ := ioutil.ReadAll(res.Body)
+		return fmt.Errorf("provider returned status %d scaling function %s: %s", res.StatusCode, functionName, string(respBody))
+	}
+
+	return nil
+}
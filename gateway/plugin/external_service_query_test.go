@@ -0,0 +1,158 @@
+// Copyright (c) OpenFaaS Author(s). All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// failingAuthInjector always fails, to exercise the auth-injection error path.
+type failingAuthInjector struct{}
+
+func (failingAuthInjector) Inject(r *http.Request) error {
+	return errors.New("injection failed")
+}
+
+func newTestQuery(t *testing.T, server *httptest.Server) ExternalServiceQuery {
+	t.Helper()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("unable to parse test server URL: %s", err)
+	}
+
+	return NewExternalServiceQuery(*u, nil)
+}
+
+func Test_ExternalServiceQuery_GetReplicas_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"replicas":2,"maxReplicas":5,"minReplicas":1,"scalingInProgress":false,"availableReplicas":2}`))
+	}))
+	defer server.Close()
+
+	query := newTestQuery(t, server)
+
+	response, err := query.GetReplicas("fn one")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if response.Replicas != 2 || response.MaxReplicas != 5 || response.MinReplicas != 1 || response.AvailableReplicas != 2 {
+		t.Fatalf("unexpected response: %+v", response)
+	}
+}
+
+func Test_ExternalServiceQuery_GetReplicas_EscapesFunctionName(t *testing.T) {
+	var gotRequestURI string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotRequestURI = r.RequestURI
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	query := newTestQuery(t, server)
+
+	if _, err := query.GetReplicas("fn/secret"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if gotRequestURI != "/function/fn%2Fsecret" {
+		t.Fatalf("expected functionName to be path-escaped, got RequestURI %q", gotRequestURI)
+	}
+}
+
+func Test_ExternalServiceQuery_GetReplicas_NonOKStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("boom"))
+	}))
+	defer server.Close()
+
+	query := newTestQuery(t, server)
+
+	_, err := query.GetReplicas("fn1")
+	if err == nil {
+		t.Fatal("expected an error for a non-200 response")
+	}
+}
+
+func Test_ExternalServiceQuery_GetReplicas_MalformedBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("not json"))
+	}))
+	defer server.Close()
+
+	query := newTestQuery(t, server)
+
+	_, err := query.GetReplicas("fn1")
+	if err == nil {
+		t.Fatal("expected an error for a malformed response body")
+	}
+}
+
+func Test_ExternalServiceQuery_GetReplicas_AuthInjectionFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("request should not reach the server when auth injection fails")
+	}))
+	defer server.Close()
+
+	query := newTestQuery(t, server)
+	query.Auth = failingAuthInjector{}
+
+	_, err := query.GetReplicas("fn1")
+	if err == nil {
+		t.Fatal("expected an error when auth injection fails")
+	}
+}
+
+func Test_ExternalServiceQuery_SetReplicas_Success(t *testing.T) {
+	var gotBody scaleFunctionRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	query := newTestQuery(t, server)
+
+	if err := query.SetReplicas("fn1", 3); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if gotBody.ServiceName != "fn1" || gotBody.Replicas != 3 {
+		t.Fatalf("unexpected request body: %+v", gotBody)
+	}
+}
+
+func Test_ExternalServiceQuery_SetReplicas_NonOKStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	query := newTestQuery(t, server)
+
+	if err := query.SetReplicas("fn1", 3); err == nil {
+		t.Fatal("expected an error for a non-200/202 response")
+	}
+}
+
+func Test_ExternalServiceQuery_SetReplicas_AuthInjectionFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("request should not reach the server when auth injection fails")
+	}))
+	defer server.Close()
+
+	query := newTestQuery(t, server)
+	query.Auth = failingAuthInjector{}
+
+	if err := query.SetReplicas("fn1", 3); err == nil {
+		t.Fatal("expected an error when auth injection fails")
+	}
+}
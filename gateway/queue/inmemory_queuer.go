@@ -0,0 +1,24 @@
+// Copyright (c) OpenFaaS Author(s). All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package queue
+
+import "sync"
+
+// InMemoryQueuer is a RequestQueuer that holds queued requests in
+// process memory. It is intended for tests and for single-instance
+// deployments that want the async fallback behaviour without a NATS
+// dependency.
+type InMemoryQueuer struct {
+	mu       sync.Mutex
+	Requests []*Request
+}
+
+// Queue appends req to Requests
+func (q *InMemoryQueuer) Queue(req *Request) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.Requests = append(q.Requests, req)
+	return nil
+}
@@ -0,0 +1,29 @@
+// Copyright (c) OpenFaaS Author(s). All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+
+	stan "github.com/nats-io/stan.go"
+)
+
+// NATSQueuer publishes Requests onto a NATS Streaming subject, mirroring
+// the queue-worker integration used by the gateway's existing async
+// invocation path.
+type NATSQueuer struct {
+	Conn    stan.Conn
+	Subject string
+}
+
+// Queue publishes req to the configured NATS Streaming subject
+func (q *NATSQueuer) Queue(req *Request) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("unable to marshal queue request: %s", err)
+	}
+
+	return q.Conn.Publish(q.Subject, body)
+}
@@ -0,0 +1,42 @@
+// Copyright (c) OpenFaaS Author(s). All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+// Package queue decouples the gateway from any one asynchronous
+// invocation backend, so that callers can fall back to queued delivery
+// (e.g. when a function's cold start is taking too long) without the
+// caller needing to know whether that queue is NATS Streaming or
+// something else entirely.
+package queue
+
+import "net/http"
+
+// Request is queued for asynchronous invocation of a function
+type Request struct {
+	// Function is the name of the function to invoke
+	Function string
+
+	// Body is the original request body
+	Body []byte
+
+	// Method is the original HTTP method
+	Method string
+
+	// QueryString is the original request's raw query string, without the
+	// leading "?"
+	QueryString string
+
+	// Header is the original request's headers
+	Header http.Header
+
+	// CallbackURL receives the result of the invocation, if set
+	CallbackURL string
+
+	// CallID uniquely identifies this queued request so a caller can
+	// correlate it with the eventual callback
+	CallID string
+}
+
+// RequestQueuer enqueues a Request for later, asynchronous delivery
+type RequestQueuer interface {
+	Queue(req *Request) error
+}